@@ -0,0 +1,218 @@
+// Command marker builds an HTML report showing, for every logger call site found in a source
+// tree, which tests (if any) were observed to hit it. It is the other half of the marker
+// package's stated purpose: an inverse index from code to tests, built from a coverage report
+// produced by running `go test ./...` with the marker/cover package enabled (see
+// cover.EnvEnable) and a TestMain wired up with marker.Report.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"html/template"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/KasonBraley/marker/cover"
+)
+
+func main() {
+	coverPath := flag.String("cover", "marker-cover.json", "path to the JSON report produced by marker.Report")
+	srcDir := flag.String("src", ".", "root of the source tree to scan for logger call sites")
+	outPath := flag.String("out", "marker-cover.html", "path to write the HTML report to")
+	flag.Parse()
+
+	if err := run(*coverPath, *srcDir, *outPath); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(coverPath, srcDir, outPath string) error {
+	entries, err := readEntries(coverPath)
+	if err != nil {
+		return fmt.Errorf("reading coverage report: %w", err)
+	}
+
+	sites, err := findCallSites(srcDir)
+	if err != nil {
+		return fmt.Errorf("scanning %s: %w", srcDir, err)
+	}
+
+	report := buildReport(sites, entries)
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return reportTemplate.Execute(f, report)
+}
+
+func readEntries(path string) ([]cover.Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []cover.Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// callSite is a single call to a logger method with a literal message, found in the source
+// tree.
+type callSite struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// loggerMethods are the [slog.Logger] methods (and their *Context variants) that take a
+// message as their first (or, after a leading context, second) argument.
+var loggerMethods = map[string]bool{
+	"Debug": true, "Info": true, "Warn": true, "Error": true,
+	"DebugContext": true, "InfoContext": true, "WarnContext": true, "ErrorContext": true,
+}
+
+// findCallSites walks root looking for calls to a logger method (matched by method name; the
+// receiver's type isn't checked) whose message argument is a string literal. Only literal
+// messages are indexed, since a dynamically built message can't be matched back to a source
+// location by a coverage report recording the rendered string.
+//
+// Parsing with go/ast, rather than matching source text directly, avoids false positives from
+// comments and string literals that merely look like a logger call.
+func findCallSites(root string) ([]callSite, error) {
+	var sites []callSite
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "vendor" || d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !loggerMethods[sel.Sel.Name] {
+				return true
+			}
+
+			msgIdx := 0
+			if strings.HasSuffix(sel.Sel.Name, "Context") {
+				msgIdx = 1
+			}
+			if len(call.Args) <= msgIdx {
+				return true
+			}
+			lit, ok := call.Args[msgIdx].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+			pos := fset.Position(lit.Pos())
+			sites = append(sites, callSite{File: pos.Filename, Line: pos.Line, Message: msg})
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+// siteReport pairs a call site with the tests observed to hit it.
+type siteReport struct {
+	callSite
+	Tests []string
+}
+
+// buildReport attributes each call site to the tests that logged its exact message. Messages
+// are matched exactly, not by substring: two unrelated log lines where one happens to contain
+// the other as a substring (e.g. "retrying" and "retrying request") must not be conflated.
+func buildReport(sites []callSite, entries []cover.Entry) []siteReport {
+	testsByMessage := map[string][]string{}
+	for _, e := range entries {
+		testsByMessage[e.Mark] = append(testsByMessage[e.Mark], e.Test)
+	}
+
+	report := make([]siteReport, 0, len(sites))
+	for _, site := range sites {
+		tests := append([]string(nil), testsByMessage[site.Message]...)
+		sort.Strings(tests)
+		report = append(report, siteReport{callSite: site, Tests: tests})
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].File != report[j].File {
+			return report[i].File < report[j].File
+		}
+		return report[i].Line < report[j].Line
+	})
+
+	return report
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>marker coverage report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.uncovered { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>marker coverage report</h1>
+<table>
+<tr><th>Call site</th><th>Message</th><th>Tests</th></tr>
+{{range .}}
+<tr>
+<td>{{.File}}:{{.Line}}</td>
+<td>{{.Message}}</td>
+{{if .Tests}}
+<td>{{range .Tests}}{{.}}<br>{{end}}</td>
+{{else}}
+<td class="uncovered">no test observed</td>
+{{end}}
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KasonBraley/marker/cover"
+)
+
+func TestFindCallSites(t *testing.T) {
+	dir := t.TempDir()
+	src := `// Package sample does things.
+//
+// In test code, you see ` + "`logger.Debug(\"request sent, waiting on response\")`" + ` in the code
+// and can grep for that log message.
+package sample
+
+import "log/slog"
+
+func run(logger *slog.Logger) {
+	logger.Info("retrying")
+	logger.InfoContext(nil, "retrying request")
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "sample.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sites, err := findCallSites(dir)
+	if err != nil {
+		t.Fatalf("findCallSites() = %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, s := range sites {
+		got[s.Message] = true
+	}
+
+	if got["request sent, waiting on response"] {
+		t.Error("findCallSites matched inside a doc comment, want it ignored")
+	}
+	if !got["retrying"] || !got["retrying request"] {
+		t.Errorf("findCallSites missed a real call site, got %v", got)
+	}
+	if len(sites) != 2 {
+		t.Errorf("len(sites) = %d, want 2", len(sites))
+	}
+}
+
+func TestBuildReport_ExactMatch(t *testing.T) {
+	sites := []callSite{
+		{File: "mark_test.go", Line: 111, Message: "retrying"},
+	}
+	entries := []cover.Entry{
+		// This test only ever logged "retrying request", which merely contains "retrying"
+		// as a substring. It must not be attributed to the "retrying" call site.
+		{Test: "TestUnrelated", Mark: "retrying request"},
+		{Test: "TestRetry", Mark: "retrying"},
+	}
+
+	report := buildReport(sites, entries)
+	if len(report) != 1 {
+		t.Fatalf("len(report) = %d, want 1", len(report))
+	}
+
+	want := []string{"TestRetry"}
+	if len(report[0].Tests) != len(want) || report[0].Tests[0] != want[0] {
+		t.Errorf("Tests = %v, want %v", report[0].Tests, want)
+	}
+}
+
+func TestBuildReport_Uncovered(t *testing.T) {
+	sites := []callSite{
+		{File: "mark.go", Line: 1, Message: "never logged"},
+	}
+
+	report := buildReport(sites, nil)
+	if len(report) != 1 {
+		t.Fatalf("len(report) = %d, want 1", len(report))
+	}
+	if len(report[0].Tests) != 0 {
+		t.Errorf("Tests = %v, want empty", report[0].Tests)
+	}
+}
@@ -0,0 +1,88 @@
+// Package cover records which tests observed which marks, so that a separate tool (see
+// cmd/marker) can build an inverse index from log-line call sites back to the tests that
+// exercise them.
+//
+// Recording is opt-in: the marker handler only calls Record while EnvEnable is set, so that
+// normal test runs pay no cost. Record itself does not check EnvEnable. A typical TestMain
+// looks like:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(marker.Report(m))
+//	}
+//
+// and the report is produced by running tests with MARKER_COVER=1.
+package cover
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// EnvEnable is the environment variable that, when set to a truthy value, turns on coverage
+// recording for the duration of the test binary.
+const EnvEnable = "MARKER_COVER"
+
+// EnvOutput names the environment variable giving the path coverage data is written to by
+// Write. If unset, Write defaults to "marker-cover.json".
+const EnvOutput = "MARKER_COVER_OUT"
+
+const defaultOutput = "marker-cover.json"
+
+// Entry records that mark was observed while test was running.
+type Entry struct {
+	Test string `json:"test"`
+	Mark string `json:"mark"`
+}
+
+var (
+	mu      sync.Mutex
+	entries = map[Entry]struct{}{}
+)
+
+// Enabled reports whether coverage recording has been turned on via EnvEnable.
+func Enabled() bool {
+	switch os.Getenv(EnvEnable) {
+	case "", "0", "false":
+		return false
+	default:
+		return true
+	}
+}
+
+// Record notes that mark was observed while test was running. Recording the same (test, mark)
+// pair more than once is a no-op.
+func Record(test, mark string) {
+	mu.Lock()
+	defer mu.Unlock()
+	entries[Entry{Test: test, Mark: mark}] = struct{}{}
+}
+
+// Entries returns every (test, mark) pair recorded so far, in no particular order.
+func Entries() []Entry {
+	mu.Lock()
+	defer mu.Unlock()
+
+	out := make([]Entry, 0, len(entries))
+	for e := range entries {
+		out = append(out, e)
+	}
+	return out
+}
+
+// Write serializes every recorded entry as JSON to the path named by EnvOutput (or
+// defaultOutput if unset).
+func Write() error {
+	path := os.Getenv(EnvOutput)
+	if path == "" {
+		path = defaultOutput
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(Entries())
+}
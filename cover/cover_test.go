@@ -0,0 +1,58 @@
+package cover_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KasonBraley/marker/cover"
+)
+
+func TestEnabled(t *testing.T) {
+	t.Setenv(cover.EnvEnable, "")
+	if cover.Enabled() {
+		t.Error("Enabled() = true, want false for an unset env var")
+	}
+
+	t.Setenv(cover.EnvEnable, "1")
+	if !cover.Enabled() {
+		t.Error("Enabled() = false, want true")
+	}
+}
+
+func TestRecordAndWrite(t *testing.T) {
+	cover.Record("TestFoo", "request sent")
+	cover.Record("TestFoo", "request sent")
+	cover.Record("TestBar", "request sent")
+
+	out := filepath.Join(t.TempDir(), "cover.json")
+	t.Setenv(cover.EnvOutput, out)
+
+	if err := cover.Write(); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+
+	var got []cover.Entry
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+
+	want := map[cover.Entry]bool{
+		{Test: "TestFoo", Mark: "request sent"}: true,
+		{Test: "TestBar", Mark: "request sent"}: true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for _, e := range got {
+		if !want[e] {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package marker
+
+import (
+	"container/list"
+	"log/slog"
+	"sync"
+)
+
+const (
+	defaultDedupeLimit     = 1
+	defaultDedupeCacheSize = 1024
+)
+
+// DedupeMode controls what a [handler] configured with [WithDedupe] does once it has seen a
+// log line (keyed by level and message) more than once.
+type DedupeMode int
+
+const (
+	// DedupeDrop silently discards log lines after the first occurrence.
+	DedupeDrop DedupeMode = iota
+	// DedupeCount lets every occurrence of a log line through to the inner handler, but still
+	// tracks how many times it has been seen. [Mark.ExpectUnique] does not read this count; it
+	// counts a mark's own hits, whichever mode (if any) is set.
+	DedupeCount
+	// DedupeError fails the enclosing test, via t.Errorf, each time a log line is logged again
+	// after its first occurrence. The limit of one occurrence is fixed; [WithDedupe] offers no
+	// way to change it. The test must be attached to the record's context with [NewContext]
+	// for the failure to be reported; otherwise the duplicate is only tracked.
+	DedupeError
+)
+
+// WithDedupe enables deduplication of repeated log lines, keyed by level and message, for a
+// [handler] created by [NewHandler]. Repeats are tracked in an LRU-bounded cache so that a
+// long-running test binary with many distinct log lines doesn't grow memory without bound.
+// mode controls what happens once a line has repeated; see the DedupeMode constants.
+func WithDedupe(mode DedupeMode) Option {
+	return func(h *handler) {
+		h.dedupe = newDedupeState(mode, defaultDedupeLimit, defaultDedupeCacheSize)
+	}
+}
+
+type dedupeKey struct {
+	level   slog.Level
+	message string
+}
+
+type dedupeEntry struct {
+	key   dedupeKey
+	count int
+}
+
+// dedupeState tracks how many times each (level, message) pair has been seen, bounded by an
+// LRU eviction policy.
+type dedupeState struct {
+	mode  DedupeMode
+	limit int
+
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[dedupeKey]*list.Element
+}
+
+func newDedupeState(mode DedupeMode, limit, capacity int) *dedupeState {
+	return &dedupeState{
+		mode:     mode,
+		limit:    limit,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[dedupeKey]*list.Element),
+	}
+}
+
+// observe records an occurrence of (level, message) and returns the number of times it has now
+// been seen. The least recently seen key is evicted once the LRU bound is exceeded.
+func (d *dedupeState) observe(level slog.Level, message string) int {
+	key := dedupeKey{level: level, message: message}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if el, ok := d.entries[key]; ok {
+		d.order.MoveToFront(el)
+		entry := el.Value.(*dedupeEntry)
+		entry.count++
+		return entry.count
+	}
+
+	entry := &dedupeEntry{key: key, count: 1}
+	d.entries[key] = d.order.PushFront(entry)
+
+	if d.order.Len() > d.capacity {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.entries, oldest.Value.(*dedupeEntry).key)
+	}
+
+	return entry.count
+}
@@ -0,0 +1,42 @@
+package marker_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/KasonBraley/marker"
+)
+
+func TestWithDedupe_Drop(t *testing.T) {
+	ctx := marker.NewContext(context.Background(), t)
+	h, rec := marker.NewRecorder()
+	logger := slog.New(marker.NewHandler(h, marker.WithDedupe(marker.DedupeDrop)))
+
+	logger.InfoContext(ctx, "loop iteration")
+	logger.InfoContext(ctx, "loop iteration")
+	logger.InfoContext(ctx, "loop iteration")
+
+	if got := rec.All().Len(); got != 1 {
+		t.Fatalf("got %d records, want 1 after deduping", got)
+	}
+}
+
+func TestWithDedupe_ExpectUnique(t *testing.T) {
+	ctx := marker.NewContext(context.Background(), t)
+	logger := slog.New(marker.NewHandler(slog.NewTextHandler(io.Discard, nil), marker.WithDedupe(marker.DedupeCount)))
+
+	mark := marker.Check(t, "loop iteration")
+	logger.InfoContext(ctx, "loop iteration")
+	if err := mark.ExpectUnique(); err != nil {
+		t.Error(err)
+	}
+
+	mark = marker.Check(t, "loop iteration")
+	logger.InfoContext(ctx, "loop iteration")
+	logger.InfoContext(ctx, "loop iteration")
+	if err := mark.ExpectUnique(); err == nil {
+		t.Error("expected error for a line logged twice, got nil")
+	}
+}
@@ -14,41 +14,144 @@ package marker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+
+	"github.com/KasonBraley/marker/cover"
 )
 
-type state struct {
-	markName *string
-	markHit  bool
+type ctxKey struct{}
+
+// matcher decides whether a given [slog.Record] satisfies a registered mark.
+type matcher interface {
+	match(r slog.Record) bool
+}
+
+// stringMatcher matches records whose message contains the given substring, the original
+// (and still the most common) way to register a mark.
+type stringMatcher string
+
+func (s stringMatcher) match(r slog.Record) bool {
+	return strings.Contains(r.Message, string(s))
+}
+
+// funcMatcher matches records using an arbitrary predicate.
+type funcMatcher func(slog.Record) bool
+
+func (f funcMatcher) match(r slog.Record) bool {
+	return f(r)
+}
+
+// regexMatcher matches records whose message matches a regular expression.
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) match(r slog.Record) bool {
+	return m.re.MatchString(r.Message)
+}
+
+// attrMatcher matches records carrying a top-level attribute equal to key/value.
+type attrMatcher struct {
+	key   string
+	value any
+}
+
+func (m attrMatcher) match(r slog.Record) bool {
+	return recordHasAttr(r, m.key, m.value)
+}
+
+// markState tracks a single registered mark: how it's matched, any level restriction, and how
+// many times it needs to be, and has been, hit.
+type markState struct {
+	matcher  matcher
+	level    *slog.Level
+	minCount int
+	count    int
+}
+
+// markSet holds the marks a single test is currently expecting to see. Keying mark state
+// off *testing.T (rather than a single global) lets parallel subtests, and code under test
+// that fans out to goroutines, each register their own expected marks without colliding.
+type markSet struct {
+	mu    sync.Mutex
+	marks map[string]*markState
 }
 
-// Stores the currently active mark and its hit count.
-// State is not synchronized and assumes single threaded execution.
-var globalState = state{}
+// markSets holds the active markSet for every *testing.T with at least one mark pending.
+var markSets sync.Map // map[*testing.T]*markSet
+
+func setFor(t *testing.T) *markSet {
+	s, _ := markSets.LoadOrStore(t, &markSet{marks: make(map[string]*markState)})
+	return s.(*markSet)
+}
+
+// markIDCounter gives CheckFunc marks a unique, human-readable name, since a function value
+// can't otherwise be rendered or compared for the purposes of the duplicate-registration check.
+var markIDCounter atomic.Uint64
 
 type handler struct {
-	h slog.Handler
+	h      slog.Handler
+	dedupe *dedupeState
 }
 
+// Option configures a [handler] returned by [NewHandler].
+type Option func(*handler)
+
 // NewHandler returns a [slog.Handler] implementation to help trace tests to source code.
 // In a test environment, reported by [testing.Testing], the [slog.Handler] returned records
-// that a log message was hit.
+// that a log message was hit for whichever *testing.T was attached to the record's context
+// with [NewContext].
 //
 // In a test, [Check] is used to say that the code under test should log a specific message. It
 // returns a [Mark] where [Mark.ExpectHit] is expected to be called after the code under test
 // is ran.
 //
 // In non-tests(i.e. normal code operation), this recording of [Mark]'s is a no-op.
-func NewHandler(h slog.Handler) *handler {
-	return &handler{h: h}
+func NewHandler(h slog.Handler, opts ...Option) *handler {
+	hd := &handler{h: h}
+	for _, opt := range opts {
+		opt(hd)
+	}
+	return hd
+}
+
+// NewContext returns a copy of ctx carrying t, so that [handler.Handle] can attribute a log
+// record to the test that is expecting it. Code under test should thread this context through
+// to its [slog.Logger] calls (e.g. via InfoContext) for marks to be recorded correctly, in
+// particular under t.Parallel() or when the code under test logs from other goroutines.
+func NewContext(ctx context.Context, t *testing.T) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
 }
 
 func (m *handler) Handle(ctx context.Context, r slog.Record) error {
-	if testing.Testing() {
-		recordMark(r.Message)
+	t, hasT := ctx.Value(ctxKey{}).(*testing.T)
+
+	if testing.Testing() && hasT {
+		recordMark(t, r)
+		if cover.Enabled() {
+			cover.Record(t.Name(), r.Message)
+		}
+	}
+
+	if m.dedupe != nil {
+		count := m.dedupe.observe(r.Level, r.Message)
+		switch m.dedupe.mode {
+		case DedupeDrop:
+			if count > 1 {
+				return nil
+			}
+		case DedupeError:
+			if count > m.dedupe.limit && hasT {
+				t.Errorf("mark: %q (level %s) logged %d times, want at most %d", r.Message, r.Level, count, m.dedupe.limit)
+			}
+		}
 	}
 
 	return m.h.Handle(ctx, r)
@@ -59,88 +162,210 @@ func (m *handler) Enabled(ctx context.Context, lvl slog.Level) bool {
 }
 
 func (m *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return m.h.WithAttrs(attrs)
+	return &handler{h: m.h.WithAttrs(attrs), dedupe: m.dedupe}
 }
 
 func (m *handler) WithGroup(name string) slog.Handler {
-	return m.h.WithGroup(name)
+	return &handler{h: m.h.WithGroup(name), dedupe: m.dedupe}
 }
 
-func recordMark(msg string) {
-	if globalState.markName != nil {
-		activeMark := *globalState.markName
-		if strings.Contains(msg, activeMark) {
-			globalState.markHit = true
+func recordMark(t *testing.T, r slog.Record) {
+	v, ok := markSets.Load(t)
+	if !ok {
+		return
+	}
+
+	s := v.(*markSet)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ms := range s.marks {
+		if ms.level != nil && r.Level != *ms.level {
+			continue
+		}
+		if ms.matcher.match(r) {
+			ms.count++
 		}
 	}
 }
 
 type Mark struct {
+	t    *testing.T
 	name string
 }
 
-// Check stores the given mark name in global state to be subsequently asserted it was hit
-// with [Mark.ExpectHit].
+// Check registers name as a mark expected to be hit by t, to be subsequently asserted with
+// [Mark.ExpectHit]. A mark is hit by any record whose message contains name. Marks are scoped
+// to t, so concurrent (e.g. parallel) tests may each Check their own marks without colliding.
 //
 // Check will panic if not used in a testing environment, as reported by [testing.Testing].
-func Check(name string) Mark {
+func Check(t *testing.T, name string) Mark {
+	return newMark(t, name, stringMatcher(name))
+}
+
+// CheckFunc registers a mark expected to be hit by t whenever match returns true for a record.
+func CheckFunc(t *testing.T, match func(slog.Record) bool) Mark {
+	name := fmt.Sprintf("func#%d", markIDCounter.Add(1))
+	return newMark(t, name, funcMatcher(match))
+}
+
+// CheckRegex registers a mark expected to be hit by t whenever a record's message matches re.
+func CheckRegex(t *testing.T, re *regexp.Regexp) Mark {
+	name := fmt.Sprintf("regex %q", re.String())
+	return newMark(t, name, regexMatcher{re: re})
+}
+
+// CheckAttr registers a mark expected to be hit by t whenever a record carries a top-level
+// attribute named key equal to value.
+func CheckAttr(t *testing.T, key string, value any) Mark {
+	name := fmt.Sprintf("attr %s=%v", key, value)
+	return newMark(t, name, attrMatcher{key: key, value: value})
+}
+
+func newMark(t *testing.T, name string, match matcher) Mark {
 	if !testing.Testing() {
 		panic("mark: marker.Check can only be used in tests")
 	}
+	t.Helper()
 
-	if globalState.markName != nil {
+	s := setFor(t)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.marks[name]; exists {
 		// This is possible to happen, due to misuse of the API. For instance, this would occur
-		// if two [Check] calls are called in a row without a corresponding [Mark.ExpectHit] call.
+		// if two [Check] calls are made for the same name in a row without a corresponding
+		// [Mark.ExpectHit] call.
 		//
 		// Like:
-		// mark := marker.Check("foo")
-		// mark2 := marker.Check("foo2")
-		//
-		panic(fmt.Sprintf("mark: mark name %q should be nil, missing the corresponding ExpectHit call", name))
+		// mark := marker.Check(t, "foo")
+		// mark2 := marker.Check(t, "foo")
+		panic(fmt.Sprintf("mark: mark name %q already registered for this test, missing the corresponding ExpectHit call", name))
 	}
 
-	if globalState.markHit {
-		// This should never happen.
-		panic(fmt.Sprintf("mark: hit count should be false for mark %q", name))
+	s.marks[name] = &markState{matcher: match, minCount: 1}
+	return Mark{t: t, name: name}
+}
+
+// CheckAll registers all of names as marks expected to be hit by t, to be subsequently asserted
+// together with [MarkSet.ExpectAllHit]. This is useful for asserting that several log lines all
+// fired during a single operation under test.
+func CheckAll(t *testing.T, names ...string) MarkSet {
+	t.Helper()
+
+	marks := make([]Mark, 0, len(names))
+	for _, name := range names {
+		marks = append(marks, Check(t, name))
 	}
+	return MarkSet{marks: marks}
+}
 
-	globalState.markName = &name
-	return Mark{name: name}
+// MarkSet is a group of marks registered together via [CheckAll].
+type MarkSet struct {
+	marks []Mark
 }
 
-// ExpectHit returns an error if the stored name on Mark was not hit. ExpectHit requires [Check]
-// to have been called first with the mark name that you expect to have been logged in the function
-// under test.
+// ExpectAllHit returns a joined error for every mark in the set that was not hit.
+func (ms MarkSet) ExpectAllHit() error {
+	var errs []error
+	for _, m := range ms.marks {
+		if err := m.ExpectHit(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithLevel refines m to only count records logged at exactly level. It must be called before
+// the code under test runs.
+func (m Mark) WithLevel(level slog.Level) Mark {
+	m.withState(func(ms *markState) { ms.level = &level })
+	return m
+}
+
+// WithMinCount refines m to require at least n matching records, instead of the default of one.
+// It must be called before the code under test runs.
+func (m Mark) WithMinCount(n int) Mark {
+	m.withState(func(ms *markState) { ms.minCount = n })
+	return m
+}
+
+func (m Mark) withState(fn func(*markState)) {
+	s := setFor(m.t)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ms, ok := s.marks[m.name]; ok {
+		fn(ms)
+	}
+}
+
+// ExpectHit returns an error if m was not hit at least as many times as required. ExpectHit
+// requires [Check] (or one of its variants) to have been called first with the mark that you
+// expect to have been logged in the function under test.
 //
 // ExpectHit will panic if not used in a testing environment, as reported by [testing.Testing].
 func (m Mark) ExpectHit() error {
+	return m.expect(func(ms *markState) error {
+		if ms.count < ms.minCount {
+			// This is the expected behavior if something went wrong.
+			// Can be one of:
+			// - The mark in the test is wrong
+			// - The corresponding log line in the code under test is wrong
+			// - Or in the real scenario this package is made for, the code under test was actually
+			// not executed like it was expected to be.
+			if ms.minCount <= 1 {
+				return fmt.Errorf("mark %q not hit", m.name)
+			}
+			return fmt.Errorf("mark %q not hit: want at least %d, got %d", m.name, ms.minCount, ms.count)
+		}
+		return nil
+	})
+}
+
+// ExpectUnique returns an error if m was not hit exactly once. Pairing this with
+// [WithDedupe] lets a test assert that a log line fired exactly once per invocation, catching
+// accidental log loops that a plain substring check would miss.
+//
+// ExpectUnique will panic if not used in a testing environment, as reported by [testing.Testing].
+func (m Mark) ExpectUnique() error {
+	return m.expect(func(ms *markState) error {
+		if ms.count == 0 {
+			return fmt.Errorf("mark %q not hit", m.name)
+		}
+		if ms.count > 1 {
+			return fmt.Errorf("mark %q hit %d times, want exactly 1", m.name, ms.count)
+		}
+		return nil
+	})
+}
+
+func (m Mark) expect(check func(*markState) error) error {
 	if !testing.Testing() {
 		panic("mark: ExpectHit can only be used in tests")
 	}
 
-	defer func() {
-		globalState = state{}
-	}()
-
-	if globalState.markName == nil {
+	if m.t == nil {
 		// This occuring means incorrect use of the API. The [Check] function was not called first.
 		panic("mark: ExpectHit called without first calling Check")
 	}
+	m.t.Helper()
 
-	if globalState.markName != nil && *globalState.markName != m.name {
-		// This should never happen.
-		panic("mark: global state does not match the given Mark")
+	s := setFor(m.t)
+	s.mu.Lock()
+	ms, ok := s.marks[m.name]
+	if ok {
+		delete(s.marks, m.name)
 	}
+	empty := len(s.marks) == 0
+	s.mu.Unlock()
 
-	if !globalState.markHit {
-		// This is the expected behavior if something went wrong.
-		// Can be one of:
-		// - The mark name in the test is wrong
-		// - The mark name(log message) in the code under test is wrong
-		// - Or in the real scenario this package is made for, the code under test was actually
-		// not executed like it was expected to be.
-		return fmt.Errorf("mark %q not hit", m.name)
+	if empty {
+		markSets.Delete(m.t)
+	}
+
+	if !ok {
+		// This should never happen.
+		panic("mark: ExpectHit called without first calling Check")
 	}
 
-	return nil
+	return check(ms)
 }
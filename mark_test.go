@@ -1,9 +1,11 @@
 package marker_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
+	"regexp"
 	"testing"
 
 	"github.com/KasonBraley/marker"
@@ -21,18 +23,19 @@ func newProduction() *productionCode {
 	return &p
 }
 
-func (p *productionCode) functionUnderTest(x int) {
+func (p *productionCode) functionUnderTest(ctx context.Context, x int) {
 	if x%2 == 0 {
-		p.logger.Info(fmt.Sprintf("x is even (x=%v)", x))
+		p.logger.InfoContext(ctx, fmt.Sprintf("x is even (x=%v)", x))
 	}
-	p.logger.Info(fmt.Sprintf("x is odd (x=%v)", x))
+	p.logger.InfoContext(ctx, fmt.Sprintf("x is odd (x=%v)", x))
 }
 
 func TestMarkLogger(t *testing.T) {
 	realCode := newProduction()
+	ctx := marker.NewContext(context.Background(), t)
 
-	mark := marker.Check("x is even")
-	realCode.functionUnderTest(2)
+	mark := marker.Check(t, "x is even")
+	realCode.functionUnderTest(ctx, 2)
 	if err := mark.ExpectHit(); err != nil {
 		t.Error(err)
 	}
@@ -46,9 +49,13 @@ func TestMarkLogger(t *testing.T) {
 	}
 
 	for name, tt := range tests {
+		tt := tt
 		t.Run(name, func(t *testing.T) {
-			mark := marker.Check(tt.markValue)
-			realCode.functionUnderTest(tt.value)
+			t.Parallel()
+
+			ctx := marker.NewContext(context.Background(), t)
+			mark := marker.Check(t, tt.markValue)
+			realCode.functionUnderTest(ctx, tt.value)
 			if err := mark.ExpectHit(); err != nil {
 				t.Error(err)
 			}
@@ -56,6 +63,77 @@ func TestMarkLogger(t *testing.T) {
 	}
 }
 
+func TestCheckAll(t *testing.T) {
+	realCode := newProduction()
+	ctx := marker.NewContext(context.Background(), t)
+
+	marks := marker.CheckAll(t, "x is even", "x is odd")
+	realCode.functionUnderTest(ctx, 2)
+	if err := marks.ExpectAllHit(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckMatchers(t *testing.T) {
+	t.Run("CheckRegex", func(t *testing.T) {
+		realCode := newProduction()
+		ctx := marker.NewContext(context.Background(), t)
+
+		mark := marker.CheckRegex(t, regexp.MustCompile(`x is (even|odd) \(x=\d+\)`))
+		realCode.functionUnderTest(ctx, 4)
+		if err := mark.ExpectHit(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("CheckAttr", func(t *testing.T) {
+		ctx := marker.NewContext(context.Background(), t)
+		logger := slog.New(marker.NewHandler(slog.NewTextHandler(io.Discard, nil)))
+
+		// The int literal here and the int attr value logged below are different Go types
+		// than what slog.Record stores internally (slog normalizes to int64), so this also
+		// guards against a naive reflect.DeepEqual-style comparison that would never match.
+		mark := marker.CheckAttr(t, "status", 500)
+		logger.InfoContext(ctx, "request failed", "status", 500)
+		if err := mark.ExpectHit(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("CheckFunc with WithLevel", func(t *testing.T) {
+		ctx := marker.NewContext(context.Background(), t)
+		logger := slog.New(marker.NewHandler(slog.NewTextHandler(io.Discard, nil)))
+
+		mark := marker.CheckFunc(t, func(r slog.Record) bool {
+			return r.Message == "retrying"
+		}).WithLevel(slog.LevelWarn)
+
+		logger.InfoContext(ctx, "retrying")
+		logger.WarnContext(ctx, "retrying")
+		if err := mark.ExpectHit(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("WithMinCount", func(t *testing.T) {
+		ctx := marker.NewContext(context.Background(), t)
+		logger := slog.New(marker.NewHandler(slog.NewTextHandler(io.Discard, nil)))
+
+		mark := marker.Check(t, "retrying").WithMinCount(2)
+		logger.InfoContext(ctx, "retrying")
+		if err := mark.ExpectHit(); err == nil {
+			t.Error("expected error, got nil")
+		}
+
+		mark = marker.Check(t, "retrying").WithMinCount(2)
+		logger.InfoContext(ctx, "retrying")
+		logger.InfoContext(ctx, "retrying")
+		if err := mark.ExpectHit(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
 func TestMarkLogger_ExpectError(t *testing.T) {
 	t.Run("ExpectHit without Check should panic", func(t *testing.T) {
 		defer func() {
@@ -70,12 +148,13 @@ func TestMarkLogger_ExpectError(t *testing.T) {
 		}()
 
 		logger := slog.New(marker.NewHandler(slog.NewTextHandler(io.Discard, nil)))
-		logger.Info("foo")
+		ctx := marker.NewContext(context.Background(), t)
+		logger.InfoContext(ctx, "foo")
 		_ = (marker.Mark{}).ExpectHit()
 	})
 
 	t.Run("No corresponding log message returns error", func(t *testing.T) {
-		mark := marker.Check("foo")
+		mark := marker.Check(t, "foo")
 		err := mark.ExpectHit()
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -86,21 +165,22 @@ func TestMarkLogger_ExpectError(t *testing.T) {
 		}
 	})
 
-	t.Run("Check without ExpectHit", func(t *testing.T) {
+	t.Run("Check called twice for same mark without ExpectHit", func(t *testing.T) {
 		defer func() {
+			want := `mark: mark name "foo" already registered for this test, missing the corresponding ExpectHit call`
 			got := recover()
 			if got == nil {
 				t.Error("expected a panic")
 			}
-			want := `mark: mark name "foo2" should be nil, missing the corresponding ExpectHit call`
 			if got != want {
 				t.Errorf("expected %q, got %v", want, got)
 			}
 		}()
 
 		logger := slog.New(marker.NewHandler(slog.NewTextHandler(io.Discard, nil)))
-		_ = marker.Check("foo")
-		_ = marker.Check("foo2")
-		logger.Info("foo")
+		ctx := marker.NewContext(context.Background(), t)
+		_ = marker.Check(t, "foo")
+		_ = marker.Check(t, "foo")
+		logger.InfoContext(ctx, "foo")
 	})
 }
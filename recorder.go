@@ -0,0 +1,169 @@
+package marker
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RecordedLogs is a filterable snapshot of [slog.Record]s captured by a [Recorder]. Filter
+// methods return a narrowed RecordedLogs, so they can be chained, e.g.:
+//
+//	rec.All().FilterMessage("request sent").FilterAttr("status", 500).Len()
+type RecordedLogs []slog.Record
+
+// FilterMessage returns the subset of logs whose message contains substr.
+func (logs RecordedLogs) FilterMessage(substr string) RecordedLogs {
+	var out RecordedLogs
+	for _, r := range logs {
+		if strings.Contains(r.Message, substr) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FilterAttr returns the subset of logs that have an attribute named key whose value equals
+// value. Attrs added via [slog.Logger.WithGroup] are not descended into; key must match a
+// top-level attribute of the record.
+func (logs RecordedLogs) FilterAttr(key string, value any) RecordedLogs {
+	var out RecordedLogs
+	for _, r := range logs {
+		if recordHasAttr(r, key, value) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Len returns the number of logs.
+func (logs RecordedLogs) Len() int {
+	return len(logs)
+}
+
+func recordHasAttr(r slog.Record, key string, value any) bool {
+	want := slog.AnyValue(value)
+
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == key && a.Value.Equal(want) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// Recorder captures every [slog.Record] passed to the [slog.Handler] returned alongside it by
+// [NewRecorder], so that tests can assert on more than a substring match against the message,
+// e.g. specific attributes, levels, or groups. It is modeled after zap's zaptest/observer.
+type Recorder struct {
+	mu      sync.Mutex
+	records RecordedLogs
+}
+
+func (r *Recorder) add(rec slog.Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+}
+
+// All returns every record observed so far, in the order they were logged.
+func (r *Recorder) All() RecordedLogs {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.records)
+}
+
+// TakeAll returns every record observed so far, in the order they were logged, and clears the
+// Recorder.
+func (r *Recorder) TakeAll() RecordedLogs {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := r.records
+	r.records = nil
+	return all
+}
+
+// FilterMessage is a shorthand for r.All().FilterMessage(substr).
+func (r *Recorder) FilterMessage(substr string) RecordedLogs {
+	return r.All().FilterMessage(substr)
+}
+
+// FilterAttr is a shorthand for r.All().FilterAttr(key, value).
+func (r *Recorder) FilterAttr(key string, value any) RecordedLogs {
+	return r.All().FilterAttr(key, value)
+}
+
+// recordHandler is the [slog.Handler] returned by [NewRecorder]. Unlike [handler], it retains
+// the attrs and groups accumulated via WithAttrs/WithGroup per-clone (rather than passing them
+// through to an inner handler), so that it can merge them into the records it captures.
+type recordHandler struct {
+	rec  *Recorder
+	goas []groupOrAttrs
+}
+
+// groupOrAttrs is either a group name (pushed by WithGroup) or a batch of attrs (pushed by
+// WithAttrs), recorded in the order the calls were made.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewRecorder returns a [slog.Handler] that records every message it handles, and the
+// [*Recorder] used to inspect what was recorded.
+func NewRecorder() (slog.Handler, *Recorder) {
+	rec := &Recorder{}
+	return &recordHandler{rec: rec}, rec
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		goa := h.goas[i]
+		if goa.group != "" {
+			attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+		} else {
+			attrs = append(slices.Clone(goa.attrs), attrs...)
+		}
+	}
+
+	rr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	rr.AddAttrs(attrs...)
+	h.rec.add(rr)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.withGroupOrAttrs(groupOrAttrs{attrs: attrs})
+}
+
+func (h *recordHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.withGroupOrAttrs(groupOrAttrs{group: name})
+}
+
+func (h *recordHandler) withGroupOrAttrs(goa groupOrAttrs) *recordHandler {
+	h2 := &recordHandler{rec: h.rec}
+	h2.goas = make([]groupOrAttrs, len(h.goas)+1)
+	copy(h2.goas, h.goas)
+	h2.goas[len(h2.goas)-1] = goa
+	return h2
+}
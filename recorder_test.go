@@ -0,0 +1,73 @@
+package marker_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/KasonBraley/marker"
+)
+
+func TestRecorder(t *testing.T) {
+	h, rec := marker.NewRecorder()
+	logger := slog.New(h)
+
+	logger.Info("request sent", "status", 200)
+	logger.Info("request sent", "status", 500)
+	logger.With("component", "worker").Info("worker failed", "status", 500)
+
+	if got := rec.All().Len(); got != 3 {
+		t.Fatalf("All().Len() = %d, want 3", got)
+	}
+
+	got := rec.FilterMessage("request sent").FilterAttr("status", 500).Len()
+	if got != 1 {
+		t.Fatalf("FilterMessage(...).FilterAttr(...).Len() = %d, want 1", got)
+	}
+
+	if got := rec.FilterAttr("component", "worker").Len(); got != 1 {
+		t.Fatalf("FilterAttr(\"component\", \"worker\").Len() = %d, want 1", got)
+	}
+
+	taken := rec.TakeAll()
+	if taken.Len() != 3 {
+		t.Fatalf("TakeAll().Len() = %d, want 3", taken.Len())
+	}
+	if rec.All().Len() != 0 {
+		t.Fatal("expected Recorder to be empty after TakeAll")
+	}
+}
+
+func TestRecorder_Groups(t *testing.T) {
+	h, rec := marker.NewRecorder()
+	logger := slog.New(h).WithGroup("http").With("status", 500)
+
+	logger.Info("request sent")
+
+	records := rec.All()
+	if records.Len() != 1 {
+		t.Fatalf("All().Len() = %d, want 1", records.Len())
+	}
+
+	var group slog.Attr
+	found := false
+	records[0].Attrs(func(a slog.Attr) bool {
+		if a.Key == "http" {
+			group, found = a, true
+			return false
+		}
+		return true
+	})
+	if !found {
+		t.Fatal("expected a top-level \"http\" group attr")
+	}
+
+	var status int64
+	for _, a := range group.Value.Group() {
+		if a.Key == "status" {
+			status = a.Value.Int64()
+		}
+	}
+	if status != 500 {
+		t.Fatalf("group attr \"status\" = %d, want 500", status)
+	}
+}
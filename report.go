@@ -0,0 +1,31 @@
+package marker
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/KasonBraley/marker/cover"
+)
+
+// Report runs m, and afterward, if coverage recording is enabled (see the marker/cover
+// package's EnvEnable), writes out every (test, mark) pair observed during the run. Call it
+// from a TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(marker.Report(m))
+//	}
+//
+// The resulting report is consumed by the cmd/marker CLI to build an index from log-line call
+// sites back to the tests that exercise them.
+func Report(m *testing.M) int {
+	code := m.Run()
+
+	if cover.Enabled() {
+		if err := cover.Write(); err != nil {
+			fmt.Fprintf(os.Stderr, "marker: writing coverage report: %v\n", err)
+		}
+	}
+
+	return code
+}